@@ -0,0 +1,196 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"strings"
+)
+
+var fixFlag = flag.Bool("fix", false, "rewrite the commit message in place, applying fixable rule corrections")
+
+// defaultBodyWrapColumn is the column applyFixes hard-wraps the body at when
+// no other value is configured.
+const defaultBodyWrapColumn = 72
+
+// separatorPattern mirrors FormatRegularPattern but captures the whitespace
+// between the header's colon and <subject> as its own group, so applyFixes
+// can tell whether rebuilding the header with a single space collapsed it.
+var separatorPattern = regexp.MustCompile(`^([a-zA-Z]+)(\(.*\))?(!)?:(\s+)(.*)$`)
+
+// FixResult is the outcome of an auto-fix pass over a commit message.
+type FixResult struct {
+	Message string   // the rewritten message, comment block preserved verbatim
+	Applied []string // human-readable description of each fix applied
+	Clean   bool     // true once the fixed message passes Verify
+}
+
+// applyFixes rewrites the fixable parts of raw: lowercasing <type>/<scope>,
+// lowercasing the first letter of <subject>, collapsing whitespace after the
+// colon, stripping a trailing period from <subject>, and hard-wrapping the
+// body. Comment lines and anything at or past the scissors line are left
+// untouched.
+func applyFixes(raw string, c Config) (FixResult, error) {
+	raw = strings.ReplaceAll(raw, "\r\n", "\n")
+	lines := strings.Split(raw, "\n")
+	content, trailer := splitCommentTrailer(lines)
+	if len(content) == 0 {
+		return FixResult{}, ErrFormat
+	}
+
+	p := regexp.MustCompile(FormatRegularPattern)
+	ss := p.FindStringSubmatch(content[0])
+	if ss == nil {
+		return FixResult{}, ErrFormat
+	}
+
+	var applied []string
+
+	t, scopeRaw, bang, subject := ss[1], ss[2], ss[3], ss[4]
+
+	if sep := separatorPattern.FindStringSubmatch(content[0]); sep != nil && sep[4] != " " {
+		applied = append(applied, "collapsed whitespace after the colon to a single space")
+	}
+
+	if lower := strings.ToLower(t); lower != t {
+		applied = append(applied, "lowercased <type>")
+		t = lower
+	}
+
+	scope := scopeRaw
+	if scope != "" {
+		inner := strings.TrimPrefix(strings.TrimSuffix(scope, ")"), "(")
+		if lower := strings.ToLower(inner); lower != inner {
+			applied = append(applied, "lowercased <scope>")
+			inner = lower
+		}
+		scope = "(" + inner + ")"
+	}
+
+	if subject != "" {
+		r := []rune(subject)
+		if lower := strings.ToLower(string(r[0])); lower != string(r[0]) {
+			applied = append(applied, "lowercased first letter of <subject>")
+			r[0] = []rune(lower)[0]
+			subject = string(r)
+		}
+	}
+
+	if trimmed := strings.TrimRight(subject, "."); trimmed != subject {
+		applied = append(applied, "stripped trailing period from <subject>")
+		subject = trimmed
+	}
+
+	content[0] = fmt.Sprintf("%s%s%s: %s", t, scope, bang, subject)
+
+	if len(content) > 1 {
+		wrapped, changed := wrapBody(content[1:], defaultBodyWrapColumn)
+		if changed {
+			applied = append(applied, fmt.Sprintf("hard-wrapped body at column %d", defaultBodyWrapColumn))
+		}
+		content = append([]string{content[0], ""}, wrapped...)
+	}
+
+	fixed := strings.Join(append(content, trailer...), "\n")
+
+	clean := false
+	if format, err := NewFormat(strings.Join(content, "\n")); err == nil {
+		clean = format.Verify(c) == nil
+	}
+
+	return FixResult{Message: fixed, Applied: applied, Clean: clean}, nil
+}
+
+// wrapBody re-wraps each blank-line-separated paragraph in lines at col
+// columns, reporting whether any paragraph was re-wrapped. A footer
+// paragraph, or one holding a `#` comment line, is kept as it is.
+func wrapBody(lines []string, col int) ([]string, bool) {
+	paragraphs := splitParagraphs(lines)
+	if len(paragraphs) == 0 {
+		return lines, false
+	}
+
+	var out []string
+	changed := false
+	for i, para := range paragraphs {
+		if i > 0 {
+			out = append(out, "")
+		}
+		keep := isFooterParagraph(para)
+		for _, l := range para {
+			if strings.HasPrefix(strings.TrimSpace(l), "#") {
+				keep = true
+			}
+		}
+		if keep {
+			out = append(out, para...)
+			continue
+		}
+		wrapped := wrapText(strings.Join(para, " "), col)
+		if strings.Join(wrapped, "\n") != strings.Join(para, "\n") {
+			changed = true
+		}
+		out = append(out, wrapped...)
+	}
+	return out, changed
+}
+
+// wrapText greedily wraps s into lines no longer than col bytes, breaking
+// only on word boundaries; a word longer than col stays whole on its own line.
+func wrapText(s string, col int) []string {
+	words := strings.Fields(s)
+	if len(words) == 0 {
+		return nil
+	}
+
+	lines := []string{words[0]}
+	for _, w := range words[1:] {
+		last := lines[len(lines)-1]
+		if len(last)+1+len(w) > col {
+			lines = append(lines, w)
+			continue
+		}
+		lines[len(lines)-1] = last + " " + w
+	}
+	return lines
+}
+
+// runFix reads the commit message file, applies every fixable correction,
+// writes the result back, and reports what remains broken (if anything).
+func runFix() (string, Config, error) {
+	conf, err := NewConfig(*r)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	raw, err := os.ReadFile(commitMsgFilePath)
+	if err != nil {
+		return "", conf, err
+	}
+
+	result, err := applyFixes(string(raw), conf)
+	if err != nil {
+		return string(raw), conf, err
+	}
+
+	if err := os.WriteFile(commitMsgFilePath, []byte(result.Message), 0o644); err != nil {
+		log.Fatal(err)
+	}
+
+	for _, a := range result.Applied {
+		fmt.Fprintf(os.Stderr, "%s %s\n", textBrightGreen("fixed:"), a)
+	}
+
+	if result.Clean {
+		return "", conf, nil
+	}
+
+	content, _ := splitCommentTrailer(strings.Split(result.Message, "\n"))
+	format, err := NewFormat(strings.Join(content, "\n"))
+	if err != nil {
+		return result.Message, conf, err
+	}
+	return result.Message, conf, format.Verify(conf)
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+const commitMsgHookScript = "#!/bin/sh\nexec go-commitlinter check \"$1\"\n"
+
+// gitHooksDir resolves the directory git runs hooks from, respecting a
+// configured core.hooksPath and falling back to the repo default.
+func gitHooksDir() string {
+	out, err := exec.Command("git", "config", "--get", "core.hooksPath").Output()
+	if err == nil {
+		if dir := strings.TrimSpace(string(out)); dir != "" {
+			return dir
+		}
+	}
+	return filepath.Join(".git", "hooks")
+}
+
+// runInstall writes a commit-msg hook that delegates to `go-commitlinter check "$1"`.
+func runInstall(args []string) {
+	dir := gitHooksDir()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		log.Fatal(err)
+	}
+
+	path := filepath.Join(dir, "commit-msg")
+	if err := os.WriteFile(path, []byte(commitMsgHookScript), 0o755); err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Printf("installed commit-msg hook at %s\n", path)
+}
+
+// runUninstall removes the commit-msg hook runInstall writes.
+func runUninstall(args []string) {
+	path := filepath.Join(gitHooksDir(), "commit-msg")
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		log.Fatal(err)
+	}
+
+	fmt.Printf("removed commit-msg hook at %s\n", path)
+}
+
+// runCheck lints the commit message file named by the first positional
+// argument after the flags, the contract git's commit-msg hook actually
+// invokes with ($1).
+func runCheck(args []string) {
+	fs := flag.NewFlagSet("check", flag.ExitOnError)
+	ruleFlag := fs.String("rule", "", "select rule file path (config.yaml)")
+	format := fs.String("format", outputFormatText, "output format: text, json, or sarif")
+	if err := fs.Parse(args); err != nil {
+		log.Fatal(err)
+	}
+
+	if fs.NArg() < 1 {
+		log.Fatal("check requires a commit message file path, e.g. `go-commitlinter check .git/COMMIT_EDITMSG`")
+	}
+
+	*r = *ruleFlag
+	*outputFormat = *format
+
+	m, conf, err := run(fs.Arg(0))
+	if *outputFormat == outputFormatJSON || *outputFormat == outputFormatSARIF {
+		report(m, conf, err)
+		return
+	}
+	finally(m, conf, err)
+}
@@ -1,10 +1,10 @@
 package main
 
 import (
-	"bufio"
 	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"regexp"
@@ -17,6 +17,7 @@ import (
 const (
 	commitMsgFilePath = ".git/COMMIT_EDITMSG"
 	defaultYamlName   = ".commitlinter.yaml"
+	scissorsLine      = "# ------------------------ >8 ------------------------"
 	formatDoc         = "<type>(<scope>): <subject>"
 	scopePattern      = `(feat|fix|perf|docs|style|refactor|test|build|chore)`
 	scopeDoc          = "The <scope> can be empty (e.g. if the change is a global or difficult to assign to a single component), in which case the parentheses are omitted."
@@ -41,7 +42,8 @@ func textBrightYellow(s string) string {
 var (
 	r = flag.String("rule", "", "select rule file path (config.yaml)")
 
-	FormatRegularPattern = `([a-zA-Z]+)(\(.*\))?:\s+(.*)`
+	FormatRegularPattern = `([a-zA-Z]+)(\(.*\))?(!)?:\s+(.*)`
+	FooterLinePattern    = `^(BREAKING CHANGE|[A-Za-z][A-Za-z-]*)(: | #)(.*)$`
 
 	errorTitle    = "============================ Invalid Message ================================"
 	errorTemplate = "\n%s\ntitle message:	%s\ncorrect format:	%s\n\n%s\n\nSee: %s\n"
@@ -52,6 +54,7 @@ var (
 	ErrFormat  = errors.New("invalid format error")
 	ErrScope   = errors.New("invalid scope error")
 	ErrSubject = errors.New("invalid subject error")
+	ErrFooter  = errors.New("invalid footer error")
 
 	DefaultConfig = Config{
 		SkipPrefixes: []string{
@@ -144,12 +147,46 @@ type Config struct {
 	ScopeDoc       string    `yaml:"scope_doc"`
 	SubjectPattern string    `yaml:"subject_pattern"`
 	SubjectDoc     string    `yaml:"subject_doc"`
+
+	// RequireBody forces a blank-line-separated body after the header.
+	RequireBody bool `yaml:"require_body"`
+	// RequireBreakingFooter forces a `BREAKING CHANGE:` footer whenever the
+	// header carries the `!` breaking marker.
+	RequireBreakingFooter bool `yaml:"require_breaking_footer"`
+	// AllowedFooterTokens restricts footer tokens to this list. An empty
+	// list means any token is allowed. `BREAKING CHANGE` is always allowed.
+	AllowedFooterTokens []string `yaml:"allowed_footer_tokens"`
+
+	// Rules configures the pluggable rule engine, keyed by rule name (see
+	// ruleFactories in rules.go). A rule left unconfigured falls back to
+	// defaultRuleSeverity.
+	Rules map[string]RuleConfig `yaml:"rules"`
+
+	// ScopeEnum, if set, restricts <scope> to an exact match against this
+	// list instead of ScopePattern.
+	ScopeEnum []string `yaml:"scope_enum"`
+	// ScopeFromPaths maps a scope name to the path globs (`**` matches any
+	// depth) that belong to it, e.g. `api: ["internal/api/**"]`. It powers
+	// the optional scope-path-match rule.
+	ScopeFromPaths map[string][]string `yaml:"scope_from_paths"`
+}
+
+// Footer is a single Conventional Commits footer, e.g. `Reviewed-by: Alice`
+// or `Refs #123`.
+type Footer struct {
+	Token string
+	Value string
 }
 
 type Format struct {
 	Type    string
 	Scope   string
 	Subject string
+	Body    string
+	Footers []Footer
+	// Breaking is true when the header carries a `!` marker before the
+	// colon, or a `BREAKING CHANGE:` footer is present.
+	Breaking bool
 }
 
 func fileExists(filename string) bool {
@@ -177,18 +214,73 @@ func NewConfig(filepath string) (Config, error) {
 	return conf, nil
 }
 
+// splitParagraphs groups lines into paragraphs separated by one or more
+// blank lines, dropping leading/trailing blank lines.
+func splitParagraphs(lines []string) [][]string {
+	var paragraphs [][]string
+	var cur []string
+	for _, l := range lines {
+		if strings.TrimSpace(l) == "" {
+			if len(cur) > 0 {
+				paragraphs = append(paragraphs, cur)
+				cur = nil
+			}
+			continue
+		}
+		cur = append(cur, l)
+	}
+	if len(cur) > 0 {
+		paragraphs = append(paragraphs, cur)
+	}
+	return paragraphs
+}
+
+// isFooterParagraph reports whether every line in the paragraph matches the
+// `Token: value` or `Token #value` footer form.
+func isFooterParagraph(lines []string) bool {
+	if len(lines) == 0 {
+		return false
+	}
+	p := regexp.MustCompile(FooterLinePattern)
+	for _, l := range lines {
+		if !p.MatchString(l) {
+			return false
+		}
+	}
+	return true
+}
+
+func parseFooters(lines []string) []Footer {
+	var footers []Footer
+	p := regexp.MustCompile(FooterLinePattern)
+	for _, l := range lines {
+		ss := p.FindStringSubmatch(l)
+		if ss == nil {
+			continue
+		}
+		footers = append(footers, Footer{Token: ss[1], Value: ss[3]})
+	}
+	return footers
+}
+
+// NewFormat parses a full Conventional Commits v1.0 message: a `<type>(<scope>)!: <subject>`
+// header, followed by an optional body and footers, each separated by a blank line.
 func NewFormat(m string) (Format, error) {
+	m = strings.ReplaceAll(m, "\r\n", "\n")
+	lines := strings.Split(m, "\n")
+
 	p, err := regexp.Compile(FormatRegularPattern)
 	if err != nil {
 		return Format{}, err
 	}
-	ss := p.FindAllStringSubmatch(m, 1)
-	if len(ss) == 0 || len(ss[0]) != 4 {
+	ss := p.FindAllStringSubmatch(lines[0], 1)
+	if len(ss) == 0 || len(ss[0]) != 5 {
 		return Format{}, ErrFormat
 	}
 
 	t := ss[0][1]
-	subject := ss[0][3]
+	bang := ss[0][3]
+	subject := ss[0][4]
 	if t == "" || subject == "" {
 		return Format{}, ErrFormat
 	}
@@ -201,84 +293,120 @@ func NewFormat(m string) (Format, error) {
 		}
 	}
 
-	f := Format{
-		Type:    t,
-		Scope:   scope,
-		Subject: subject,
-	}
-	return f, nil
-}
+	paragraphs := splitParagraphs(lines[1:])
 
-func (f Format) scopeLinter(pattern string) error {
-	if len(f.Scope) == 0 {
-		return nil
+	var bodyParagraphs [][]string
+	var footerLines []string
+	if n := len(paragraphs); n > 0 && isFooterParagraph(paragraphs[n-1]) {
+		footerLines = paragraphs[n-1]
+		bodyParagraphs = paragraphs[:n-1]
+	} else {
+		bodyParagraphs = paragraphs
 	}
 
-	matched, err := regexp.MatchString(pattern, f.Scope)
-	if err != nil || !matched {
-		return ErrStyle
+	var bodyLines []string
+	for i, para := range bodyParagraphs {
+		if i > 0 {
+			bodyLines = append(bodyLines, "")
+		}
+		bodyLines = append(bodyLines, para...)
 	}
 
-	return nil
-}
-
-func (f Format) subjectLinter(pattern string) error {
-	if !(len(f.Subject) > 0) {
-		return ErrFormat
+	footers := parseFooters(footerLines)
+	breaking := bang != ""
+	for _, ft := range footers {
+		if ft.Token == "BREAKING CHANGE" {
+			breaking = true
+		}
 	}
 
-	matched, err := regexp.MatchString(pattern, f.Subject)
-	if err != nil || !matched {
-		return ErrSubject
+	f := Format{
+		Type:     t,
+		Scope:    scope,
+		Subject:  subject,
+		Body:     strings.Join(bodyLines, "\n"),
+		Footers:  footers,
+		Breaking: breaking,
 	}
-
-	return nil
+	return f, nil
 }
 
-func (f Format) typeLinter(c Config) error {
-	for _, r := range c.TypeRules {
-		if r.Type == f.Type {
-			return nil
+func (f Format) footerLinter(c Config) error {
+	if c.RequireBreakingFooter && f.Breaking {
+		found := false
+		for _, ft := range f.Footers {
+			if ft.Token == "BREAKING CHANGE" {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return ErrFooter
 		}
 	}
-	if f.Type != strings.ToLower(f.Type) {
-		return ErrStyle
+
+	if len(c.AllowedFooterTokens) > 0 {
+		for _, ft := range f.Footers {
+			if ft.Token == "BREAKING CHANGE" {
+				continue
+			}
+			allowed := false
+			for _, token := range c.AllowedFooterTokens {
+				if ft.Token == token {
+					allowed = true
+					break
+				}
+			}
+			if !allowed {
+				return ErrFooter
+			}
+		}
 	}
 
-	return ErrType
+	return nil
 }
 
+// Verify runs the configured RuleSet against f, printing any warning-level
+// violations, then applies the Conventional Commits footer/body checks that
+// sit outside the pluggable rule engine.
 func (f Format) Verify(c Config) error {
-	if err := f.typeLinter(c); err != nil {
+	rs := NewRuleSet(c)
+	violations, err := rs.Verify(f)
+	for _, v := range violations {
+		if v.Severity == SeverityWarning {
+			fmt.Fprintf(os.Stderr, "%s %s: %s\n", textBrightYellow("warning"), v.Rule, v.Err)
+		}
+	}
+	if err != nil {
 		return err
 	}
 
-	if err := f.scopeLinter(c.ScopePattern); err != nil {
-		return err
+	if c.RequireBody && f.Body == "" {
+		return ErrFormat
 	}
 
-	if err := f.subjectLinter(c.SubjectPattern); err != nil {
+	if err := f.footerLinter(c); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func run() (string, Config, error) {
-	flag.Parse()
-
+// run lints the message read from path (".git/COMMIT_EDITMSG" in the
+// default hook flow, or "-" to read stdin).
+func run(path string) (string, Config, error) {
 	conf, err := NewConfig(*r)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	s, err := getMessage()
+	s, err := getMessage(path)
 	if err != nil {
-		return "", conf, err
+		return s, conf, err
 	}
 	for _, skipPrefix := range conf.SkipPrefixes {
 		if strings.HasPrefix(s, skipPrefix) {
-			return "", conf, nil
+			return s, conf, nil
 		}
 	}
 
@@ -290,53 +418,147 @@ func run() (string, Config, error) {
 		return s, conf, err
 	}
 
-	return "", conf, nil
+	return s, conf, nil
 }
 
 func finally(m string, conf Config, err error) {
-	message := ""
-	switch err {
-	case ErrFormat, ErrType:
+	if err == nil {
+		return
+	}
+
+	message, ok := renderError(m, conf, err)
+	if !ok {
+		log.Fatal(xerrors.Errorf("unspecified error: %w", err))
+	}
+
+	fmt.Println(message)
+	os.Exit(1)
+}
+
+// renderError builds the styled error template for a lint failure. It
+// handles both the package's sentinel errors and a *RuleError from the
+// pluggable rule engine; ok is false for anything else, meaning the caller
+// doesn't know how to render err and should treat it as unspecified.
+func renderError(m string, conf Config, err error) (message string, ok bool) {
+	var ruleErr *RuleError
+
+	switch {
+	case errors.Is(err, ErrFormat), errors.Is(err, ErrType):
 		message = fmt.Sprintf(errorTemplate, textRed(errorTitle), textRed(m), textBrightGreen(formatDoc), conf.TypeRules, textBrightGreen(conf.Reference))
-	case ErrStyle:
+	case errors.Is(err, ErrStyle):
 		message = fmt.Sprintf(errorTemplate, textRed(errorTitle), textRed(m), textBrightGreen(formatDoc), textBrightYellow(conf.StyleDoc), textBrightGreen(conf.Reference))
-	case ErrScope:
+	case errors.Is(err, ErrScope):
 		message = fmt.Sprintf(errorTemplate, textRed(errorTitle), textRed(m), textBrightGreen(formatDoc), textBrightYellow(conf.ScopeDoc), textBrightGreen(conf.Reference))
-	case ErrSubject:
+	case errors.Is(err, ErrSubject):
 		message = fmt.Sprintf(errorTemplate, textRed(errorTitle), textRed(m), textBrightGreen(formatDoc), textBrightYellow(conf.SubjectDoc), textBrightGreen(conf.Reference))
-	case nil:
-		return
+	case errors.Is(err, ErrFooter):
+		message = fmt.Sprintf(errorTemplate, textRed(errorTitle), textRed(m), textBrightGreen(formatDoc), textBrightYellow("Footers must use the `Token: value` or `Token #value` form; a breaking change requires a `BREAKING CHANGE:` footer."), textBrightGreen(conf.Reference))
+	case errors.As(err, &ruleErr):
+		message = fmt.Sprintf(errorTemplate, textRed(errorTitle), textRed(m), textBrightGreen(formatDoc), textBrightYellow(fmt.Sprintf("%s: %s", ruleErr.Rule, ruleErr.Err)), textBrightGreen(conf.Reference))
 	default:
-		log.Fatal(xerrors.Errorf("unspecified error: %w", err))
-	}
-	message = fmt.Sprintf("%s\n%s", message, textRed(footer))
-	if err != nil {
-		fmt.Println(message)
-		os.Exit(1)
+		return "", false
 	}
+
+	return fmt.Sprintf("%s\n%s", message, textRed(footer)), true
 }
 
-func getMessage() (string, error) {
-	reader := bufio.NewReader(os.Stdin)
-	b, _, _ := reader.ReadLine()
-	if len(b) != 0 {
-		return string(b), nil
+// splitCommentTrailer separates a COMMIT_EDITMSG's real content from the
+// `#`-prefixed comment block git appends at the end (and, with `commit -v`,
+// the diff below the scissors line). content is everything before that
+// block; trailer is the block itself, returned untouched.
+func splitCommentTrailer(lines []string) (content, trailer []string) {
+	cut := len(lines)
+	for i, l := range lines {
+		if l == scissorsLine {
+			cut = i
+			break
+		}
+	}
+
+	end := cut
+	for end > 0 {
+		l := lines[end-1]
+		if l == "" || strings.HasPrefix(strings.TrimSpace(l), "#") {
+			end--
+			continue
+		}
+		break
 	}
+	return lines[:end], lines[end:]
+}
 
-	f, err := os.Open(commitMsgFilePath)
+// getMessage reads the commit message from path, the way git actually
+// invokes the commit-msg hook (`commit-msg $1`). path == "-" reads stdin
+// instead, for backward compatibility with the old pipe-based invocation.
+// Comment lines and anything at or past the scissors marker are stripped
+// before the message is handed to the linter.
+func getMessage(path string) (string, error) {
+	var raw []byte
+	var err error
+	if path == "-" {
+		raw, err = io.ReadAll(os.Stdin)
+	} else {
+		raw, err = os.ReadFile(path)
+	}
 	if err != nil {
 		return "", err
 	}
+	if len(raw) == 0 {
+		return "", ErrFormat
+	}
 
-	reader = bufio.NewReader(f)
-	b, _, err = reader.ReadLine()
-	if err != nil {
+	lines := strings.Split(strings.ReplaceAll(string(raw), "\r\n", "\n"), "\n")
+	content, _ := splitCommentTrailer(lines)
+	var kept []string
+	for _, l := range content {
+		if !strings.HasPrefix(strings.TrimSpace(l), "#") {
+			kept = append(kept, l)
+		}
+	}
+	s := strings.Join(kept, "\n")
+	if strings.TrimSpace(s) == "" {
 		return "", ErrFormat
 	}
 
-	return string(b), nil
+	return s, nil
 }
 
+// main dispatches to the "lint" (default), "install", "uninstall", and
+// "check <file>" subcommands. "lint" keeps the historical flag-only
+// invocation so existing hook scripts and CI configs keep working.
 func main() {
-	finally(run())
+	args := os.Args[1:]
+	if len(args) > 0 {
+		switch args[0] {
+		case "install":
+			runInstall(args[1:])
+			return
+		case "uninstall":
+			runUninstall(args[1:])
+			return
+		case "check":
+			runCheck(args[1:])
+			return
+		case "lint":
+			os.Args = append([]string{os.Args[0]}, args[1:]...)
+		}
+	}
+
+	flag.Parse()
+	if *fixFlag {
+		finally(runFix())
+		return
+	}
+
+	path := commitMsgFilePath
+	if flag.NArg() > 0 {
+		path = flag.Arg(0)
+	}
+
+	m, conf, err := run(path)
+	if *outputFormat == outputFormatJSON || *outputFormat == outputFormatSARIF {
+		report(m, conf, err)
+		return
+	}
+	finally(m, conf, err)
 }
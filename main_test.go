@@ -1,6 +1,8 @@
 package main
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -69,10 +71,50 @@ func TestNewFormat(t *testing.T) {
 			wantErr: ErrFormat,
 		},
 		{
-			Name: "subject empty 3",
+			Name:    "subject empty 3",
 			Message: "feat(test):        		 ",
 			wantErr: ErrFormat,
 		},
+		{
+			Name:    "breaking marker",
+			Message: "feat(api)!: drop v1",
+			want: Format{
+				Type:     "feat",
+				Scope:    "api",
+				Subject:  "drop v1",
+				Breaking: true,
+			},
+			wantErr: nil,
+		},
+		{
+			Name:    "body and footers",
+			Message: "fix(api): handle nil response\n\nThe client now returns an error instead of panicking.\n\nRefs #42\nReviewed-by: Alice",
+			want: Format{
+				Type:    "fix",
+				Scope:   "api",
+				Subject: "handle nil response",
+				Body:    "The client now returns an error instead of panicking.",
+				Footers: []Footer{
+					{Token: "Refs", Value: "42"},
+					{Token: "Reviewed-by", Value: "Alice"},
+				},
+			},
+			wantErr: nil,
+		},
+		{
+			Name:    "breaking change footer",
+			Message: "fix(api): handle nil response\n\nBREAKING CHANGE: the client no longer retries automatically",
+			want: Format{
+				Type:    "fix",
+				Scope:   "api",
+				Subject: "handle nil response",
+				Footers: []Footer{
+					{Token: "BREAKING CHANGE", Value: "the client no longer retries automatically"},
+				},
+				Breaking: true,
+			},
+			wantErr: nil,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -141,3 +183,139 @@ func TestVerify(t *testing.T) {
 		})
 	}
 }
+
+func TestVerifyRuleEngine(t *testing.T) {
+	c, _ := NewConfig("")
+	c.Rules = map[string]RuleConfig{
+		"subject-max-length": {Severity: SeverityError, Max: 10},
+		"no-trailing-period": {Severity: SeverityError},
+	}
+
+	f, err := NewFormat("feat(test): a subject far longer than ten characters.")
+	assert.NoError(t, err)
+	assert.Error(t, f.Verify(c))
+
+	f, err = NewFormat("feat(test): ok")
+	assert.NoError(t, err)
+	assert.NoError(t, f.Verify(c))
+}
+
+func TestRenderErrorRuleEngine(t *testing.T) {
+	c, _ := NewConfig("")
+	c.Rules = map[string]RuleConfig{
+		"subject-max-length": {Severity: SeverityError, Max: 10},
+	}
+
+	f, err := NewFormat("feat(test): a subject far longer than ten characters")
+	assert.NoError(t, err)
+
+	verifyErr := f.Verify(c)
+	assert.Error(t, verifyErr)
+
+	message, ok := renderError("feat(test): a subject far longer than ten characters", c, verifyErr)
+	assert.True(t, ok)
+	assert.Contains(t, message, "subject-max-length")
+	assert.Contains(t, message, "subject exceeds 10 characters")
+}
+
+func TestImperativeMoodRule(t *testing.T) {
+	rule := imperativeMoodRule{
+		denylistWords:    defaultImperativeDenylistWords,
+		denylistSuffixes: defaultImperativeDenylistSuffixes,
+	}
+
+	f, err := NewFormat("feat(test): added a widget")
+	assert.NoError(t, err)
+	assert.Error(t, rule.Check(f))
+
+	f, err = NewFormat("feat(test): add a widget")
+	assert.NoError(t, err)
+	assert.NoError(t, rule.Check(f))
+}
+
+func TestApplyFixes(t *testing.T) {
+	c, _ := NewConfig("")
+
+	raw := "Feat(Test):   Added a widget.\n# Please enter the commit message for your changes.\n# Lines starting with '#' will be ignored.\n"
+	result, err := applyFixes(raw, c)
+	assert.NoError(t, err)
+	assert.Equal(t, "feat(test): added a widget\n# Please enter the commit message for your changes.\n# Lines starting with '#' will be ignored.\n", result.Message)
+	assert.NotEmpty(t, result.Applied)
+	assert.Contains(t, result.Applied, "collapsed whitespace after the colon to a single space")
+}
+
+func TestFindings(t *testing.T) {
+	c, _ := NewConfig("")
+	c.Rules = map[string]RuleConfig{
+		"no-trailing-period": {Severity: SeverityError},
+	}
+
+	f, err := NewFormat("feat(test): ship it.")
+	assert.NoError(t, err)
+
+	findings := f.Findings(c)
+	assert.Len(t, findings, 1)
+	assert.Equal(t, "no-trailing-period", findings[0].Rule)
+	assert.Equal(t, SeverityError, findings[0].Severity)
+}
+
+func TestBuildFindingsSkipsSkippedPrefix(t *testing.T) {
+	c, _ := NewConfig("")
+
+	_, findings := buildFindings("Merge branch 'main' into feature", c, nil)
+	assert.Empty(t, findings)
+}
+
+func TestGetMessageStripsCommentsAndScissors(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "COMMIT_EDITMSG")
+	raw := "feat(test): add widget\n\nsome body text\n# Please enter the commit message for your changes.\n# ------------------------ >8 ------------------------\ndiff --git a/foo b/foo\n"
+	assert.NoError(t, os.WriteFile(path, []byte(raw), 0o644))
+
+	m, err := getMessage(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "feat(test): add widget\n\nsome body text", m)
+}
+
+func TestScopeEnum(t *testing.T) {
+	c, _ := NewConfig("")
+	c.ScopeEnum = []string{"api", "cli"}
+
+	f, err := NewFormat("feat(api): add endpoint")
+	assert.NoError(t, err)
+	assert.NoError(t, f.Verify(c))
+
+	f, err = NewFormat("feat(web): add page")
+	assert.NoError(t, err)
+	assert.Equal(t, ErrScope.Error(), f.Verify(c).Error())
+}
+
+func TestScopePathMatchRule(t *testing.T) {
+	rule := scopePathMatchRule{
+		mapping: map[string][]string{
+			"api": {"internal/api/**"},
+			"cli": {"cmd/**"},
+		},
+		stagedFiles: []string{"cmd/root.go"},
+	}
+
+	f, err := NewFormat("feat(cli): add flag")
+	assert.NoError(t, err)
+	assert.NoError(t, rule.Check(f))
+
+	f, err = NewFormat("feat(api): add flag")
+	assert.NoError(t, err)
+	assert.Error(t, rule.Check(f))
+}
+
+func TestVerifyRequireBreakingFooter(t *testing.T) {
+	c, _ := NewConfig("")
+	c.RequireBreakingFooter = true
+
+	f, err := NewFormat("feat(test)!: drop v1")
+	assert.NoError(t, err)
+	assert.Equal(t, ErrFooter.Error(), f.Verify(c).Error())
+
+	f, err = NewFormat("feat(test)!: drop v1\n\nBREAKING CHANGE: v1 endpoints are removed")
+	assert.NoError(t, err)
+	assert.NoError(t, f.Verify(c))
+}
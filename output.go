@@ -0,0 +1,229 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+)
+
+const (
+	outputFormatText  = "text"
+	outputFormatJSON  = "json"
+	outputFormatSARIF = "sarif"
+
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://raw.githubusercontent.com/oasis-tcs/sarif-spec/master/Schemata/sarif-schema-2.1.0.json"
+	toolName     = "go-commitlinter"
+)
+
+var outputFormat = flag.String("format", outputFormatText, "output format: text, json, or sarif")
+
+// Finding is a single rule violation surfaced by a lint pass, shaped for
+// machine-readable output (JSON/SARIF) rather than the human errorTemplate.
+type Finding struct {
+	Rule     string
+	Severity Severity
+	Message  string
+	DocURL   string
+}
+
+// Findings runs every configured rule against f, plus the footer/body
+// checks outside the rule engine, without stopping at the first failure.
+func (f Format) Findings(c Config) []Finding {
+	var findings []Finding
+
+	rs := NewRuleSet(c)
+	violations, _ := rs.Verify(f)
+	for _, v := range violations {
+		findings = append(findings, Finding{
+			Rule:     v.Rule,
+			Severity: v.Severity,
+			Message:  v.Err.Error(),
+			DocURL:   c.Reference,
+		})
+	}
+
+	if c.RequireBody && f.Body == "" {
+		findings = append(findings, Finding{
+			Rule:     "require-body",
+			Severity: SeverityError,
+			Message:  "commit message is missing a body",
+			DocURL:   c.Reference,
+		})
+	}
+
+	if err := f.footerLinter(c); err != nil {
+		findings = append(findings, Finding{
+			Rule:     "footer",
+			Severity: SeverityError,
+			Message:  err.Error(),
+			DocURL:   c.Reference,
+		})
+	}
+
+	return findings
+}
+
+type jsonError struct {
+	Rule    string `json:"rule"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	DocURL  string `json:"doc_url"`
+}
+
+type jsonOutput struct {
+	Message  string      `json:"message"`
+	Type     string      `json:"type"`
+	Scope    string      `json:"scope"`
+	Subject  string      `json:"subject"`
+	Breaking bool        `json:"breaking"`
+	Errors   []jsonError `json:"errors"`
+}
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name string `json:"name"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+// sarifLevel maps a rule Severity to the SARIF result.level vocabulary.
+func sarifLevel(s Severity) string {
+	switch s {
+	case SeverityWarning:
+		return "warning"
+	case SeverityOff:
+		return "note"
+	default:
+		return "error"
+	}
+}
+
+// buildFindings reparses raw to recover the Format for machine-readable
+// reporting. If parsing itself failed, the parse error becomes the single
+// finding since there is no Format to run rules against. A message matching
+// one of conf.SkipPrefixes (e.g. "Merge ") was never linted in the first
+// place, so it reports no findings rather than a spurious parse failure.
+func buildFindings(raw string, conf Config, err error) (Format, []Finding) {
+	if err == nil {
+		for _, skipPrefix := range conf.SkipPrefixes {
+			if strings.HasPrefix(raw, skipPrefix) {
+				return Format{}, nil
+			}
+		}
+	}
+
+	format, ferr := NewFormat(raw)
+	if ferr != nil {
+		return Format{}, []Finding{{Rule: "format", Severity: SeverityError, Message: ferr.Error(), DocURL: conf.Reference}}
+	}
+	return format, format.Findings(conf)
+}
+
+// report prints raw's lint result as JSON or SARIF per *outputFormat, then
+// exits non-zero if err (the outcome of run()) is non-nil.
+func report(raw string, conf Config, err error) {
+	format, findings := buildFindings(raw, conf, err)
+
+	switch *outputFormat {
+	case outputFormatJSON:
+		printJSON(raw, format, findings)
+	case outputFormatSARIF:
+		printSARIF(findings)
+	}
+
+	if err != nil {
+		os.Exit(1)
+	}
+}
+
+func printJSON(raw string, format Format, findings []Finding) {
+	out := jsonOutput{
+		Message:  raw,
+		Type:     format.Type,
+		Scope:    format.Scope,
+		Subject:  format.Subject,
+		Breaking: format.Breaking,
+	}
+	for _, fd := range findings {
+		out.Errors = append(out.Errors, jsonError{
+			Rule:    fd.Rule,
+			Code:    fd.Rule,
+			Message: fd.Message,
+			DocURL:  fd.DocURL,
+		})
+	}
+
+	b, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(string(b))
+}
+
+func printSARIF(findings []Finding) {
+	l := sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs: []sarifRun{
+			{
+				Tool: sarifTool{Driver: sarifDriver{Name: toolName}},
+			},
+		},
+	}
+
+	for _, fd := range findings {
+		l.Runs[0].Results = append(l.Runs[0].Results, sarifResult{
+			RuleID:  fd.Rule,
+			Level:   sarifLevel(fd.Severity),
+			Message: sarifMessage{Text: fd.Message},
+			Locations: []sarifLocation{
+				{PhysicalLocation: sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: commitMsgFilePath}}},
+			},
+		})
+	}
+
+	b, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(string(b))
+}
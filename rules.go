@@ -0,0 +1,389 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Severity controls whether a rule violation fails the lint (error), is
+// merely reported (warning), or is ignored entirely (off).
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityOff     Severity = "off"
+)
+
+// RuleConfig configures a single named rule loaded from the `rules:` map in
+// `.commitlinter.yaml`. Fields a rule doesn't need are simply ignored.
+type RuleConfig struct {
+	Severity  Severity `yaml:"severity"`
+	Pattern   string   `yaml:"pattern"`
+	Max       int      `yaml:"max"`
+	Min       int      `yaml:"min"`
+	Denylist  []string `yaml:"denylist"`
+	Overrides []string `yaml:"overrides"`
+}
+
+// Rule is a single, independently configurable lint check run against a
+// parsed commit Format.
+type Rule interface {
+	Name() string
+	Check(f Format) error
+}
+
+// Violation pairs a rule failure with the severity it was configured at.
+type Violation struct {
+	Rule     string
+	Severity Severity
+	Err      error
+}
+
+// RuleError wraps a rule violation that isn't one of the package's sentinel
+// errors (ErrFormat, ErrType, ErrStyle, ErrScope, ErrSubject, ErrFooter), so
+// callers like finally can still render it through the standard error
+// template instead of treating it as unspecified.
+type RuleError struct {
+	Rule string
+	Err  error
+}
+
+func (e *RuleError) Error() string { return e.Err.Error() }
+
+func (e *RuleError) Unwrap() error { return e.Err }
+
+// isSentinelError reports whether err is one of go-commitlinter's known
+// sentinel errors, which finally already renders with a dedicated message.
+func isSentinelError(err error) bool {
+	switch err {
+	case ErrFormat, ErrType, ErrStyle, ErrScope, ErrSubject, ErrFooter:
+		return true
+	default:
+		return false
+	}
+}
+
+// RuleSet is an ordered, configured collection of Rules built from a
+// Config's `rules:` map.
+type RuleSet struct {
+	rules      []Rule
+	severities map[string]Severity
+}
+
+// Verify runs every rule in rs against f. It returns every violation found,
+// plus the first error-severity violation's error (nil if there was none).
+func (rs RuleSet) Verify(f Format) ([]Violation, error) {
+	var violations []Violation
+	var firstErr error
+	for _, rule := range rs.rules {
+		if err := rule.Check(f); err != nil {
+			v := Violation{Rule: rule.Name(), Severity: rs.severities[rule.Name()], Err: err}
+			violations = append(violations, v)
+			if v.Severity == SeverityError && firstErr == nil {
+				if isSentinelError(err) {
+					firstErr = err
+				} else {
+					firstErr = &RuleError{Rule: rule.Name(), Err: err}
+				}
+			}
+		}
+	}
+	return violations, firstErr
+}
+
+// defaultRuleOrder is the fixed evaluation order of the built-in rules.
+var defaultRuleOrder = []string{
+	"type-enum",
+	"scope-pattern",
+	"subject-pattern",
+	"subject-max-length",
+	"subject-min-length",
+	"subject-imperative-mood",
+	"header-max-length",
+	"body-max-line-length",
+	"no-trailing-period",
+	"scope-path-match",
+}
+
+// defaultRuleSeverity returns the severity a rule runs at when it is absent
+// from Config.Rules. Only the three checks go-commitlinter has always
+// enforced default to "error"; the rest are opt-in.
+func defaultRuleSeverity(name string) Severity {
+	switch name {
+	case "type-enum", "scope-pattern", "subject-pattern":
+		return SeverityError
+	default:
+		return SeverityOff
+	}
+}
+
+var defaultImperativeDenylistWords = []string{"added", "fixed", "updated", "removed", "changed", "refactored"}
+var defaultImperativeDenylistSuffixes = []string{"ed", "ing"}
+
+// ruleFactories maps a rule name to a constructor that bakes its RuleConfig
+// (and, where needed, the ambient Config) into a Rule instance.
+var ruleFactories = map[string]func(rc RuleConfig, c Config) Rule{
+	"type-enum": func(rc RuleConfig, c Config) Rule {
+		return typeEnumRule{typeRules: c.TypeRules}
+	},
+	"scope-pattern": func(rc RuleConfig, c Config) Rule {
+		return scopePatternRule{pattern: orDefault(rc.Pattern, c.ScopePattern), enum: c.ScopeEnum}
+	},
+	"subject-pattern": func(rc RuleConfig, c Config) Rule {
+		return subjectPatternRule{pattern: orDefault(rc.Pattern, c.SubjectPattern)}
+	},
+	"subject-max-length": func(rc RuleConfig, c Config) Rule {
+		return subjectMaxLengthRule{max: orDefaultInt(rc.Max, 72)}
+	},
+	"subject-min-length": func(rc RuleConfig, c Config) Rule {
+		return subjectMinLengthRule{min: orDefaultInt(rc.Min, 1)}
+	},
+	"subject-imperative-mood": func(rc RuleConfig, c Config) Rule {
+		return imperativeMoodRule{
+			denylistWords:    orDefaultList(rc.Denylist, defaultImperativeDenylistWords),
+			denylistSuffixes: defaultImperativeDenylistSuffixes,
+			overrides:        rc.Overrides,
+		}
+	},
+	"header-max-length": func(rc RuleConfig, c Config) Rule {
+		return headerMaxLengthRule{max: orDefaultInt(rc.Max, 100)}
+	},
+	"body-max-line-length": func(rc RuleConfig, c Config) Rule {
+		return bodyMaxLineLengthRule{max: orDefaultInt(rc.Max, 100)}
+	},
+	"no-trailing-period": func(rc RuleConfig, c Config) Rule {
+		return noTrailingPeriodRule{}
+	},
+	"scope-path-match": func(rc RuleConfig, c Config) Rule {
+		files, _ := stagedFiles()
+		return scopePathMatchRule{mapping: c.ScopeFromPaths, stagedFiles: files}
+	},
+}
+
+func orDefault(v, def string) string {
+	if v == "" {
+		return def
+	}
+	return v
+}
+
+func orDefaultInt(v, def int) int {
+	if v == 0 {
+		return def
+	}
+	return v
+}
+
+func orDefaultList(v, def []string) []string {
+	if len(v) == 0 {
+		return def
+	}
+	return v
+}
+
+// NewRuleSet builds the configured RuleSet for c, skipping any rule whose
+// resolved severity is "off".
+func NewRuleSet(c Config) RuleSet {
+	rs := RuleSet{severities: map[string]Severity{}}
+	for _, name := range defaultRuleOrder {
+		rc, configured := c.Rules[name]
+		if !configured {
+			rc = RuleConfig{}
+		}
+		if rc.Severity == "" {
+			rc.Severity = defaultRuleSeverity(name)
+		}
+		if rc.Severity == SeverityOff {
+			continue
+		}
+
+		factory, ok := ruleFactories[name]
+		if !ok {
+			continue
+		}
+		rs.rules = append(rs.rules, factory(rc, c))
+		rs.severities[name] = rc.Severity
+	}
+	return rs
+}
+
+type typeEnumRule struct {
+	typeRules TypeRules
+}
+
+func (r typeEnumRule) Name() string { return "type-enum" }
+
+func (r typeEnumRule) Check(f Format) error {
+	for _, tr := range r.typeRules {
+		if tr.Type == f.Type {
+			return nil
+		}
+	}
+	if f.Type != strings.ToLower(f.Type) {
+		return ErrStyle
+	}
+	return ErrType
+}
+
+type scopePatternRule struct {
+	pattern string
+	enum    []string
+}
+
+func (r scopePatternRule) Name() string { return "scope-pattern" }
+
+func (r scopePatternRule) Check(f Format) error {
+	if len(f.Scope) == 0 {
+		return nil
+	}
+
+	if len(r.enum) > 0 {
+		for _, s := range r.enum {
+			if s == f.Scope {
+				return nil
+			}
+		}
+		return ErrScope
+	}
+
+	matched, err := regexp.MatchString(r.pattern, f.Scope)
+	if err != nil || !matched {
+		return ErrStyle
+	}
+	return nil
+}
+
+type subjectPatternRule struct {
+	pattern string
+}
+
+func (r subjectPatternRule) Name() string { return "subject-pattern" }
+
+func (r subjectPatternRule) Check(f Format) error {
+	if !(len(f.Subject) > 0) {
+		return ErrFormat
+	}
+	matched, err := regexp.MatchString(r.pattern, f.Subject)
+	if err != nil || !matched {
+		return ErrSubject
+	}
+	return nil
+}
+
+type subjectMaxLengthRule struct {
+	max int
+}
+
+func (r subjectMaxLengthRule) Name() string { return "subject-max-length" }
+
+func (r subjectMaxLengthRule) Check(f Format) error {
+	if len(f.Subject) > r.max {
+		return fmt.Errorf("subject exceeds %d characters", r.max)
+	}
+	return nil
+}
+
+type subjectMinLengthRule struct {
+	min int
+}
+
+func (r subjectMinLengthRule) Name() string { return "subject-min-length" }
+
+func (r subjectMinLengthRule) Check(f Format) error {
+	if len(f.Subject) < r.min {
+		return fmt.Errorf("subject is shorter than %d characters", r.min)
+	}
+	return nil
+}
+
+type headerMaxLengthRule struct {
+	max int
+}
+
+func (r headerMaxLengthRule) Name() string { return "header-max-length" }
+
+func (r headerMaxLengthRule) Check(f Format) error {
+	header := f.Type
+	if f.Scope != "" {
+		header += "(" + f.Scope + ")"
+	}
+	if f.Breaking {
+		header += "!"
+	}
+	header += ": " + f.Subject
+	if len(header) > r.max {
+		return fmt.Errorf("header exceeds %d characters", r.max)
+	}
+	return nil
+}
+
+type bodyMaxLineLengthRule struct {
+	max int
+}
+
+func (r bodyMaxLineLengthRule) Name() string { return "body-max-line-length" }
+
+func (r bodyMaxLineLengthRule) Check(f Format) error {
+	if f.Body == "" {
+		return nil
+	}
+	for _, line := range strings.Split(f.Body, "\n") {
+		if len(line) > r.max {
+			return fmt.Errorf("body line exceeds %d characters", r.max)
+		}
+	}
+	return nil
+}
+
+type noTrailingPeriodRule struct{}
+
+func (r noTrailingPeriodRule) Name() string { return "no-trailing-period" }
+
+func (r noTrailingPeriodRule) Check(f Format) error {
+	if strings.HasSuffix(f.Subject, ".") {
+		return errors.New("subject must not end with a period")
+	}
+	return nil
+}
+
+// imperativeMoodRule rejects subjects whose first word looks past-tense or
+// gerund, per the Conventional Commits convention of an imperative subject
+// ("add feature", not "added feature").
+type imperativeMoodRule struct {
+	denylistWords    []string
+	denylistSuffixes []string
+	overrides        []string
+}
+
+func (r imperativeMoodRule) Name() string { return "subject-imperative-mood" }
+
+func (r imperativeMoodRule) Check(f Format) error {
+	words := strings.Fields(f.Subject)
+	if len(words) == 0 {
+		return nil
+	}
+	first := strings.ToLower(words[0])
+
+	for _, o := range r.overrides {
+		if strings.ToLower(o) == first {
+			return nil
+		}
+	}
+
+	for _, w := range r.denylistWords {
+		if w == first {
+			return fmt.Errorf("subject must be written in the imperative mood (e.g. \"add\" not %q)", first)
+		}
+	}
+
+	for _, suf := range r.denylistSuffixes {
+		if strings.HasSuffix(first, suf) {
+			return fmt.Errorf("subject must be written in the imperative mood (e.g. \"add\" not %q)", first)
+		}
+	}
+
+	return nil
+}
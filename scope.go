@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// stagedFiles returns the paths staged for the next commit, via
+// `git diff --cached --name-only`.
+func stagedFiles() ([]string, error) {
+	out, err := exec.Command("git", "diff", "--cached", "--name-only").Output()
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, l := range strings.Split(string(out), "\n") {
+		if l = strings.TrimSpace(l); l != "" {
+			files = append(files, l)
+		}
+	}
+	return files, nil
+}
+
+// globMatch reports whether name matches pattern, where `*` matches any run
+// of characters within a path segment and `**` matches across segments.
+func globMatch(pattern, name string) bool {
+	var sb strings.Builder
+	sb.WriteString("^")
+	for i := 0; i < len(pattern); {
+		switch {
+		case strings.HasPrefix(pattern[i:], "**"):
+			sb.WriteString(".*")
+			i += 2
+		case pattern[i] == '*':
+			sb.WriteString("[^/]*")
+			i++
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(pattern[i])))
+			i++
+		}
+	}
+	sb.WriteString("$")
+
+	re, err := regexp.Compile(sb.String())
+	if err != nil {
+		return false
+	}
+	return re.MatchString(name)
+}
+
+func matchesAny(patterns, files []string) bool {
+	for _, p := range patterns {
+		for _, f := range files {
+			if globMatch(p, f) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// scopePathMatchRule cross-references the declared <scope> against the
+// staged files, via Config.ScopeFromPaths, and suggests a better-fitting
+// scope when the declared one doesn't cover anything that was staged.
+type scopePathMatchRule struct {
+	mapping     map[string][]string
+	stagedFiles []string
+}
+
+func (r scopePathMatchRule) Name() string { return "scope-path-match" }
+
+func (r scopePathMatchRule) Check(f Format) error {
+	if f.Scope == "" || len(r.mapping) == 0 {
+		return nil
+	}
+
+	if patterns, ok := r.mapping[f.Scope]; ok && matchesAny(patterns, r.stagedFiles) {
+		return nil
+	}
+
+	var suggestions []string
+	for scope, patterns := range r.mapping {
+		if scope == f.Scope {
+			continue
+		}
+		if matchesAny(patterns, r.stagedFiles) {
+			suggestions = append(suggestions, scope)
+		}
+	}
+	if len(suggestions) == 0 {
+		return nil
+	}
+	sort.Strings(suggestions)
+
+	return fmt.Errorf("scope %q doesn't match the staged files; scopes that would match: %s", f.Scope, strings.Join(suggestions, ", "))
+}